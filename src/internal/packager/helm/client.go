@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/defenseunicorns/zarf/src/pkg/utils"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Client abstracts the Helm SDK operations Zarf needs, selected per chart via
+// Chart.HelmVersion (e.g. "v3.6", "v3.11"). NOTE: this binary only vendors a single
+// helm.sh/helm/v3 SDK, so today HelmVersion only changes which chart sources a version
+// permits (see v36Client, which rejects oci:// as v3.6 predates it) - Template/Install/
+// Upgrade always run against the vendored SDK regardless of which version is pinned.
+// Pinning a version is not yet a way to render against genuinely different Helm release
+// semantics.
+type Client interface {
+	// Version reports the Helm SDK version this client implements.
+	Version() string
+	// DownloadChart resolves and downloads h.Chart, returning the path to the chart archive.
+	DownloadChart(h *Helm) (string, error)
+	// Template renders h.Chart's manifests without talking to a cluster.
+	Template(h *Helm, chart *chart.Chart, values map[string]any) (string, error)
+	// Install installs h.Chart into the cluster h.actionConfig targets.
+	Install(h *Helm, chart *chart.Chart, values map[string]any) (*release.Release, error)
+	// Upgrade upgrades the existing release of h.Chart.
+	Upgrade(h *Helm, chart *chart.Chart, values map[string]any) (*release.Release, error)
+}
+
+// defaultClient is the Client charts render against when they don't pin a
+// Chart.HelmVersion.
+var defaultClient Client = &v3Client{version: "v3.11"}
+
+// clientsByVersion is every Helm SDK capability set this build of Zarf vendors, keyed by
+// the Chart.HelmVersion a component can pin.
+var clientsByVersion = map[string]Client{
+	"v3.11": defaultClient,
+	"v3.6":  &v36Client{v3Client{version: "v3.6"}},
+}
+
+// client selects the Client h.Chart should render through, failing fast if the chart
+// pins a Helm SDK version this Zarf binary doesn't vendor.
+func (h *Helm) client() (Client, error) {
+	if h.Chart.HelmVersion == "" {
+		return defaultClient, nil
+	}
+
+	if client, ok := clientsByVersion[h.Chart.HelmVersion]; ok {
+		return client, nil
+	}
+
+	return nil, fmt.Errorf("chart %s requests helm SDK %s, but this zarf binary only vendors %s",
+		h.Chart.Name, h.Chart.HelmVersion, supportedClientVersions())
+}
+
+// SelectedClientVersion reports the Helm SDK version that will render h.Chart, without
+// downloading or templating it. Intended for `zarf package inspect` to show which Helm SDK
+// a component's chart is pinned to, but that command lives outside this package and isn't
+// wired up to call it yet. NOTE: rendering itself doesn't yet vary by version (see the
+// Client doc comment), so today this is informational plus an oci:// gate, not a guarantee
+// of different render output.
+func (h *Helm) SelectedClientVersion() (string, error) {
+	client, err := h.client()
+	if err != nil {
+		return "", err
+	}
+
+	return client.Version(), nil
+}
+
+func supportedClientVersions() string {
+	versions := make([]string, 0, len(clientsByVersion))
+	for version := range clientsByVersion {
+		versions = append(versions, version)
+	}
+
+	return strings.Join(versions, ", ")
+}
+
+// chartCacheKey identifies a chart download that can be shared across components.
+type chartCacheKey struct {
+	repo, name, version, clientVersion string
+}
+
+var (
+	chartDownloadCache   = map[chartCacheKey]string{}
+	chartDownloadCacheMu sync.Mutex
+)
+
+// downloadChart resolves the client pinned for h.Chart and downloads it, reusing a
+// previous download when another component already fetched the same
+// (repo, name, version, clientVersion) tuple. A reused download is copied into
+// h.BasePath/charts (unless a file is already there) so h loads it from its own path.
+func (h *Helm) downloadChart() (string, error) {
+	client, err := h.client()
+	if err != nil {
+		return "", err
+	}
+
+	key := chartCacheKey{repo: h.Chart.Repo, name: h.Chart.Name, version: h.Chart.Version, clientVersion: client.Version()}
+
+	chartDownloadCacheMu.Lock()
+	if path, ok := chartDownloadCache[key]; ok {
+		chartDownloadCacheMu.Unlock()
+		destFile := StandardName(filepath.Join(h.BasePath, "charts"), h.Chart) + ".tgz"
+		if path != destFile {
+			if _, err := os.Stat(destFile); os.IsNotExist(err) {
+				if err := utils.CreatePathAndCopy(path, destFile); err != nil {
+					return "", fmt.Errorf("unable to stage cached chart %s: %w", h.Chart.Name, err)
+				}
+			}
+		}
+		return destFile, nil
+	}
+	chartDownloadCacheMu.Unlock()
+
+	path, err := client.DownloadChart(h)
+	if err != nil {
+		return "", err
+	}
+
+	chartDownloadCacheMu.Lock()
+	chartDownloadCache[key] = path
+	chartDownloadCacheMu.Unlock()
+
+	return path, nil
+}
+
+// v3Client is the Client implementation backed by the helm.sh/helm/v3 SDK this binary
+// is linked against.
+type v3Client struct {
+	version string
+}
+
+func (c *v3Client) Version() string {
+	return c.version
+}
+
+func (c *v3Client) DownloadChart(h *Helm) (string, error) {
+	destFile := StandardName(filepath.Join(h.BasePath, "charts"), h.Chart) + ".tgz"
+
+	switch {
+	case strings.HasPrefix(h.Chart.URL, "oci://"):
+		if _, err := h.loadChartFromOCI(); err != nil {
+			return "", err
+		}
+	case h.Chart.Repo != "":
+		if _, err := h.loadChartFromRepo(); err != nil {
+			return "", err
+		}
+	}
+
+	return destFile, nil
+}
+
+func (c *v3Client) Template(h *Helm, ch *chart.Chart, values map[string]any) (string, error) {
+	client := action.NewInstall(h.actionConfig)
+	client.DryRun = true
+	client.ReleaseName = h.Chart.ReleaseName
+	client.Replace = true
+	client.ClientOnly = true
+
+	rel, err := client.Run(ch, values)
+	if err != nil {
+		return "", fmt.Errorf("unable to render chart %s with helm %s: %w", h.Chart.Name, c.version, err)
+	}
+
+	return rel.Manifest, nil
+}
+
+func (c *v3Client) Install(h *Helm, ch *chart.Chart, values map[string]any) (*release.Release, error) {
+	client := action.NewInstall(h.actionConfig)
+	client.ReleaseName = h.Chart.ReleaseName
+	client.Namespace = h.Chart.Namespace
+
+	return client.Run(ch, values)
+}
+
+func (c *v3Client) Upgrade(h *Helm, ch *chart.Chart, values map[string]any) (*release.Release, error) {
+	client := action.NewUpgrade(h.actionConfig)
+	client.Namespace = h.Chart.Namespace
+
+	return client.Run(h.Chart.ReleaseName, ch, values)
+}
+
+// v36Client is Client for charts pinning Chart.HelmVersion "v3.6". It only vendors one
+// real behavioral difference from v3Client: v3.6 predates Helm's OCI registry support
+// (stabilized in v3.8), so it refuses oci:// chart sources instead of silently pulling
+// them with SDK behavior that version never shipped. Template/Install/Upgrade are
+// inherited unchanged from v3Client - this binary only links one helm.sh/helm/v3 SDK, so
+// there is no alternate v3.6 rendering implementation to route those through.
+type v36Client struct {
+	v3Client
+}
+
+func (c *v36Client) DownloadChart(h *Helm) (string, error) {
+	if strings.HasPrefix(h.Chart.URL, "oci://") {
+		return "", fmt.Errorf("chart %s pins helm SDK %s, which predates OCI registry support (added in v3.8)",
+			h.Chart.Name, c.version)
+	}
+
+	return c.v3Client.DownloadChart(h)
+}
+
+// loadAndRenderInputs resolves the Client h.Chart pins, ensures its archive is downloaded
+// and loaded, and merges its values - the shared setup every render/install/upgrade needs.
+func (h *Helm) loadAndRenderInputs() (Client, *chart.Chart, map[string]any, error) {
+	helmClient, err := h.client()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if h.ChartLoadOverride == "" {
+		if _, err := h.downloadChart(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	loadedChart, err := h.loadChartFromTarball()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	values, err := h.parseChartValues()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to parse values for chart %s: %w", h.Chart.Name, err)
+	}
+
+	return helmClient, loadedChart, values, nil
+}
+
+// TemplateChart renders h.Chart's manifests through the Helm SDK version it pins, without
+// talking to a cluster. Exported for the deploy/render command layer outside this package
+// to call; not yet wired up to a caller in this package.
+func (h *Helm) TemplateChart() (string, error) {
+	helmClient, loadedChart, values, err := h.loadAndRenderInputs()
+	if err != nil {
+		return "", err
+	}
+
+	return helmClient.Template(h, loadedChart, values)
+}
+
+// InstallChart installs h.Chart into the cluster h.actionConfig targets, through the Helm
+// SDK version it pins. Exported for the deploy command layer outside this package to
+// call; not yet wired up to a caller in this package.
+func (h *Helm) InstallChart() (*release.Release, error) {
+	helmClient, loadedChart, values, err := h.loadAndRenderInputs()
+	if err != nil {
+		return nil, err
+	}
+
+	return helmClient.Install(h, loadedChart, values)
+}
+
+// UpgradeChart upgrades the existing release of h.Chart, through the Helm SDK version it
+// pins. Exported for the deploy command layer outside this package to call; not yet wired
+// up to a caller in this package.
+func (h *Helm) UpgradeChart() (*release.Release, error) {
+	helmClient, loadedChart, values, err := h.loadAndRenderInputs()
+	if err != nil {
+		return nil, err
+	}
+
+	return helmClient.Upgrade(h, loadedChart, values)
+}
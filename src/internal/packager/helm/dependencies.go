@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// resolveDependencies materializes every dependency declared in chartPath's Chart.yaml
+// `dependencies:` block (or the legacy requirements.yaml) into chartPath/charts, honoring
+// each dependency's repository (file://, http(s)://, and alias: schemes) and its pinned
+// version, then writes Chart.lock. condition:/tags: gates are not evaluated here: every
+// declared dependency is downloaded. This lets components bundle
+// umbrella charts without pre-flattening them and without requiring a live network at
+// deploy time.
+func (h *Helm) resolveDependencies(chartPath string, spinner *message.Spinner) error {
+	return resolveChartDependencies(h.Chart.Name, chartPath, spinner)
+}
+
+// resolveChartDependencies does the actual Chart.lock/dependencies build for chartName's
+// chart source at chartPath. Split out from resolveDependencies so it can be unit tested
+// against a fixture chart without needing a *Helm.
+func resolveChartDependencies(chartName, chartPath string, spinner *message.Spinner) error {
+	settings := cli.New()
+
+	spinner.Updatef("Resolving dependencies for chart %s", chartName)
+
+	manager := &downloader.Manager{
+		Out:              io.Discard,
+		ChartPath:        chartPath,
+		Getters:          getter.All(settings),
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+		Debug:            message.GetLogLevel() >= message.DebugLevel,
+	}
+
+	// Build() honors an existing Chart.lock when present (pinning exact versions) and
+	// otherwise resolves from Chart.yaml/requirements.yaml, writing a fresh lock afterwards.
+	if err := manager.Build(); err != nil {
+		return fmt.Errorf("unable to resolve dependencies for chart %s: %w", chartName, err)
+	}
+
+	return nil
+}
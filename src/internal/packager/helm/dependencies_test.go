@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/stretchr/testify/require"
+)
+
+const subchartYaml = `apiVersion: v2
+name: subchart
+version: 0.1.0
+`
+
+const parentChartYaml = `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+  - name: subchart
+    version: 0.1.0
+    repository: file://../subchart
+`
+
+// TestResolveChartDependenciesBuildsLocalDependency exercises Build() against a fixture
+// chart with a file:// dependency, matching how requirements.yaml/Chart.yaml
+// dependencies: are resolved from a local sibling chart without a live network.
+func TestResolveChartDependenciesBuildsLocalDependency(t *testing.T) {
+	root := t.TempDir()
+
+	subchartDir := filepath.Join(root, "subchart")
+	require.NoError(t, os.Mkdir(subchartDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(subchartDir, "Chart.yaml"), []byte(subchartYaml), 0600))
+
+	parentDir := filepath.Join(root, "parent")
+	require.NoError(t, os.Mkdir(parentDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(parentDir, "Chart.yaml"), []byte(parentChartYaml), 0600))
+
+	spinner := message.NewProgressSpinner("resolving test fixture dependencies")
+	defer spinner.Stop()
+
+	err := resolveChartDependencies("parent", parentDir, spinner)
+	require.NoError(t, err)
+
+	require.FileExists(t, filepath.Join(parentDir, "Chart.lock"))
+	require.FileExists(t, filepath.Join(parentDir, "charts", "subchart-0.1.0.tgz"))
+}
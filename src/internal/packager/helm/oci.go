@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/zarf/src/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// ociRegistryClient builds a registry.Client for h.Chart's OCI source using the
+// registry client's default credential store. Whenever utils.FindAuthForHost returns a
+// credential for host that converts to Basic auth, the client also logs in with it; this
+// happens whether or not the docker config already holds an entry for host.
+func (h *Helm) ociRegistryClient(host string) (*registry.Client, error) {
+	client, err := registry.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create OCI registry client: %w", err)
+	}
+
+	if cred := utils.FindAuthForHost(host); cred.Auth != nil {
+		if username, password, ok := basicFromAuth(cred.Auth); ok {
+			if err := client.Login(host, registry.LoginOptBasicAuth(username, password)); err != nil {
+				return nil, fmt.Errorf("unable to authenticate to OCI registry %s: %w", host, err)
+			}
+		}
+	}
+
+	return client, nil
+}
+
+// loadChartFromOCI pulls h.Chart from an OCI registry (a Chart.URL of the form
+// "oci://registry/repo/chart") the same way `helm pull oci://...` does.
+func (h *Helm) loadChartFromOCI() (*chart.Chart, error) {
+	ref := strings.TrimPrefix(h.Chart.URL, "oci://")
+	host := strings.SplitN(ref, "/", 2)[0]
+
+	client, err := h.ociRegistryClient(host)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Pull(fmt.Sprintf("%s:%s", ref, h.Chart.Version), registry.PullOptWithChart(true))
+	if err != nil {
+		return nil, fmt.Errorf("unable to pull chart %s from %s: %w", h.Chart.Name, h.Chart.URL, err)
+	}
+
+	destFile := StandardName(filepath.Join(h.BasePath, "charts"), h.Chart) + ".tgz"
+	if err := os.WriteFile(destFile, result.Chart.Data, 0644); err != nil {
+		return nil, fmt.Errorf("unable to save chart pulled from %s: %w", h.Chart.URL, err)
+	}
+
+	loadedChart, err := loader.Load(destFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load helm chart pulled from %s: %w", h.Chart.URL, err)
+	}
+
+	if err = loadedChart.Validate(); err != nil {
+		return nil, fmt.Errorf("unable to validate helm chart pulled from %s: %w", h.Chart.URL, err)
+	}
+
+	return loadedChart, nil
+}
+
+// PackageChart stages h.Chart's archive for bundling into the zarf package - resolving it
+// through downloadChart() regardless of source (tarball, chart repo, or OCI registry) -
+// and, when registryHost is non-empty, also pushes that archive to the Zarf registry as an
+// OCI artifact so air-gapped deploys can `helm upgrade oci://<registryHost>/...` directly
+// instead of needing the original chart source. This is the call `zarf package create`
+// should make once per component chart; the create command itself lives outside this
+// package and is not yet wired up to call it.
+func (h *Helm) PackageChart(registryHost string) (string, error) {
+	destFile, err := h.downloadChart()
+	if err != nil {
+		return "", err
+	}
+
+	if registryHost != "" {
+		if err := h.pushChartOCI(registryHost, destFile); err != nil {
+			return "", err
+		}
+	}
+
+	return destFile, nil
+}
+
+// pushChartOCI pushes a repackaged chart tarball to the Zarf registry as an OCI artifact
+// (matching the flux/source-controller OCIRepository model), so air-gapped deploys can
+// `helm upgrade oci://zarf-registry/...` directly instead of needing the original source.
+func (h *Helm) pushChartOCI(registryHost string, chartTarballPath string) error {
+	client, err := h.ociRegistryClient(registryHost)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(chartTarballPath)
+	if err != nil {
+		return fmt.Errorf("unable to read chart archive %s: %w", chartTarballPath, err)
+	}
+
+	ref := fmt.Sprintf("%s/%s:%s", registryHost, h.Chart.Name, h.Chart.Version)
+	if _, err := client.Push(data, ref); err != nil {
+		return fmt.Errorf("unable to push chart %s to %s: %w", h.Chart.Name, ref, err)
+	}
+
+	return nil
+}
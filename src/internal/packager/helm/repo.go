@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/zarf/src/pkg/utils"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// loadChartFromRepo downloads a chart from a remote chart repository declared via
+// Chart.Repo/Chart.Name/Chart.Version (the `helm fetch --repo` equivalent) and loads it.
+func (h *Helm) loadChartFromRepo() (*chart.Chart, error) {
+	destDir := filepath.Join(h.BasePath, "charts")
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create chart download location: %w", err)
+	}
+
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(destDir, "repositories.yaml")
+
+	pathOpts := action.ChartPathOptions{
+		RepoURL:               h.Chart.Repo,
+		Version:               h.Chart.Version,
+		Username:              h.Chart.RepoAuth.Username,
+		Password:              h.Chart.RepoAuth.Password,
+		CertFile:              h.Chart.RepoAuth.CertFile,
+		KeyFile:               h.Chart.RepoAuth.KeyFile,
+		CaFile:                h.Chart.RepoAuth.CAFile,
+		InsecureSkipTLSverify: h.Chart.RepoAuth.InsecureSkipTLSVerify,
+		PassCredentialsAll:    h.Chart.RepoAuth.PassCredentials,
+	}
+
+	// If the component didn't declare credentials, fall back to the same
+	// .git-credentials/.netrc lookup the git-based chart sources already use.
+	if pathOpts.Username == "" && pathOpts.Password == "" {
+		if cred := utils.FindAuthForHost(pathOpts.RepoURL); cred.Auth != nil {
+			if username, password, ok := basicFromAuth(cred.Auth); ok {
+				pathOpts.Username = username
+				pathOpts.Password = password
+			}
+		}
+	}
+
+	chartDownloadPath, err := pathOpts.LocateChart(h.Chart.Name, settings)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download chart %s from %s: %w", h.Chart.Name, pathOpts.RepoURL, err)
+	}
+
+	// Stage the downloaded tarball alongside the package's other charts so it survives
+	// the same way a locally-sourced chart would.
+	destFile := StandardName(destDir, h.Chart) + ".tgz"
+	if chartDownloadPath != destFile {
+		if err := utils.CreatePathAndCopy(chartDownloadPath, destFile); err != nil {
+			return nil, fmt.Errorf("unable to stage downloaded chart: %w", err)
+		}
+	}
+
+	loadedChart, err := loader.Load(destFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load helm chart downloaded from %s: %w", pathOpts.RepoURL, err)
+	}
+
+	if err = loadedChart.Validate(); err != nil {
+		return nil, fmt.Errorf("unable to validate downloaded helm chart: %w", err)
+	}
+
+	return loadedChart, nil
+}
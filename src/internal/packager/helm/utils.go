@@ -11,6 +11,8 @@ import (
 	"strconv"
 
 	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	goGitHttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
@@ -20,12 +22,25 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
-// loadChartFromTarball returns a helm chart from a tarball
+// loadChartFromTarball returns a helm chart from a tarball. For charts sourced from a
+// remote chart repository instead of a local tarball, see loadChartFromRepo.
 func (h *Helm) loadChartFromTarball() (*chart.Chart, error) {
 	// Get the path the temporary helm chart tarball
 	sourceFile := StandardName(filepath.Join(h.BasePath, "charts"), h.Chart) + ".tgz"
 	if h.ChartLoadOverride != "" {
+		// h.ChartLoadOverride points at the chart's raw, unpacked source (e.g. `zarf
+		// prepare`/`zarf package create`), so this is the point before packaging where any
+		// dependencies: block still needs to be materialized into sourceFile/charts.
 		sourceFile = h.ChartLoadOverride
+
+		spinner := message.NewProgressSpinner("Resolving dependencies for chart %s", h.Chart.Name)
+		defer spinner.Stop()
+
+		if err := h.resolveDependencies(sourceFile, spinner); err != nil {
+			return nil, err
+		}
+
+		spinner.Success()
 	}
 
 	// Load the loadedChart tarball
@@ -63,6 +78,22 @@ func (h *Helm) parseChartValues() (map[string]any, error) {
 	return valueOpts.MergeValues(providers)
 }
 
+// basicFromAuth extracts HTTP Basic credentials from a utils.FindAuthForHost result,
+// treating a bearer/oauth2 token (http.TokenAuth) as a basic password with an empty
+// username - the same convention most chart repositories and OCI registries accept for
+// PAT-based auth. ok is false if auth isn't a credential type this package knows how to
+// turn into Basic auth.
+func basicFromAuth(auth transport.AuthMethod) (username, password string, ok bool) {
+	switch a := auth.(type) {
+	case *goGitHttp.BasicAuth:
+		return a.Username, a.Password, true
+	case *goGitHttp.TokenAuth:
+		return "", a.Token, true
+	default:
+		return "", "", false
+	}
+}
+
 func (h *Helm) createActionConfig(namespace string, spinner *message.Spinner) error {
 	// OMG THIS IS SOOOO GROSS PPL... https://github.com/helm/helm/issues/8780
 	_ = os.Setenv("HELM_NAMESPACE", namespace)
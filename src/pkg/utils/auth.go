@@ -6,22 +6,35 @@ package utils
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/kevinburke/ssh_config"
+	"golang.org/x/term"
 )
 
+// Credential pairs a host path with the go-git AuthMethod that should be used to
+// authenticate requests against it. Auth may be http.BasicAuth, http.TokenAuth,
+// ssh.PublicKeys, or ssh.PublicKeysCallback (ssh-agent) depending on where the credential
+// was sourced from.
 type Credential struct {
 	Path string
-	Auth http.BasicAuth
+	Auth transport.AuthMethod
 }
 
-// FindAuthForHost finds the authentication scheme for a given host using .git-credentials then .netrc
+// FindAuthForHost finds the authentication scheme for a given host. It checks, in order,
+// .git-credentials, .netrc, the user's ~/.ssh/config (for a matching IdentityFile),
+// GIT_ASKPASS, and finally a running ssh-agent (SSH_AUTH_SOCK). The ~/.ssh/config and
+// ssh-agent lookups are only made for ssh:// and scp-like (user@host:path) URLs.
 func FindAuthForHost(baseUrl string) Credential {
 	homePath, _ := os.UserHomeDir()
 
@@ -40,20 +53,45 @@ func FindAuthForHost(baseUrl string) Credential {
 	// Combine the creds together (.netrc second because it could have a default)
 	creds := append(gitCreds, netrcCreds...)
 
-	// Will be nil unless a match is found
-	var matchedCred Credential
-
 	// Look for a match for the given host path in the creds file
 	for _, cred := range creds {
 		// An empty credPath means that we have reached the default from the .netrc
 		hasPath := strings.Contains(baseUrl, cred.Path) || cred.Path == ""
 		if hasPath {
-			matchedCred = cred
-			break
+			return cred
 		}
 	}
 
-	return matchedCred
+	// Nothing in .git-credentials or .netrc matched, fall back to SSH-based auth or
+	// GIT_ASKPASS.
+	host, user := hostAndUserFromURL(baseUrl)
+	if host == "" {
+		return Credential{}
+	}
+
+	// Only try the SSH-config/ssh-agent lookups for URLs that actually use SSH transport -
+	// an HTTPS chart-repo or git URL whose host happens to also have an unrelated Host
+	// block in ~/.ssh/config (e.g. anyone who also clones over git@github.com) must not
+	// come back with an SSH AuthMethod.
+	if isSSHTransport(baseUrl) {
+		if auth, ok := sshConfigAuth(host, user); ok {
+			return Credential{Path: host, Auth: auth}
+		}
+	}
+
+	// GIT_ASKPASS is git's own mechanism for prompting for credentials and applies to
+	// HTTP(S) URLs just as much as SSH ones, so it runs regardless of transport.
+	if auth, ok := askPassAuth(host, user); ok {
+		return Credential{Path: host, Auth: auth}
+	}
+
+	if isSSHTransport(baseUrl) {
+		if auth, ok := sshAgentAuth(user); ok {
+			return Credential{Path: host, Auth: auth}
+		}
+	}
+
+	return Credential{}
 }
 
 // credentialParser parses a user's .git-credentials file to find git creds for hosts
@@ -73,15 +111,19 @@ func credentialParser(file io.ReadCloser) []Credential {
 		if err != nil || gitUrl.Host == "" {
 			continue
 		}
+
+		username := gitUrl.User.Username()
 		password, _ := gitUrl.User.Password()
-		credential := Credential{
-			Path: gitUrl.Host,
-			Auth: http.BasicAuth{
-				Username: gitUrl.User.Username(),
-				Password: password,
-			},
+
+		var auth transport.AuthMethod
+		if username == "oauth2" || username == "x-access-token" {
+			// oauth2:<token>@host style entries are bearer tokens, not basic auth.
+			auth = &http.TokenAuth{Token: password}
+		} else {
+			auth = &http.BasicAuth{Username: username, Password: password}
 		}
-		credentials = append(credentials, credential)
+
+		credentials = append(credentials, Credential{Path: gitUrl.Host, Auth: auth})
 	}
 
 	return credentials
@@ -169,7 +211,7 @@ func netrcParser(file io.ReadCloser) []Credential {
 func appendNetrcMachine(machine map[string]string, credentials []Credential) []Credential {
 	credential := Credential{
 		Path: machine["machine"],
-		Auth: http.BasicAuth{
+		Auth: &http.BasicAuth{
 			Username: machine["login"],
 			Password: machine["password"],
 		},
@@ -177,3 +219,155 @@ func appendNetrcMachine(machine map[string]string, credentials []Credential) []C
 
 	return append(credentials, credential)
 }
+
+// isSSHTransport reports whether raw is actually transported over SSH - an ssh:// URL or
+// scp-like syntax (user@host:path) - as opposed to an http(s):// URL, which must never
+// fall back to SSH auth just because its host is non-empty.
+func isSSHTransport(raw string) bool {
+	if !strings.Contains(raw, "://") {
+		return strings.Contains(raw, "@") && strings.Contains(raw, ":")
+	}
+
+	return strings.HasPrefix(raw, "ssh://")
+}
+
+// hostAndUserFromURL pulls the host and (if present) user out of either a standard URL
+// (https://host/path, ssh://user@host/path) or scp-like SSH syntax (user@host:path).
+func hostAndUserFromURL(raw string) (host, user string) {
+	if !strings.Contains(raw, "://") && strings.Contains(raw, "@") {
+		parts := strings.SplitN(raw, "@", 2)
+		user = parts[0]
+		host = strings.SplitN(parts[1], ":", 2)[0]
+		return host, user
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", ""
+	}
+
+	return parsed.Host, parsed.User.Username()
+}
+
+// sshConfigAuth reads ~/.ssh/config looking for a Host block matching host and, if it
+// declares an IdentityFile, loads the private key it points at (prompting for a
+// passphrase if the key is encrypted).
+func sshConfigAuth(host, user string) (transport.AuthMethod, bool) {
+	homePath, _ := os.UserHomeDir()
+	configPath := filepath.Join(homePath, ".ssh", "config")
+
+	configFile, err := os.Open(configPath)
+	if err != nil {
+		return nil, false
+	}
+	defer configFile.Close()
+
+	cfg, err := ssh_config.Decode(configFile)
+	if err != nil {
+		message.Debugf("Unable to parse %s: %s", configPath, err.Error())
+		return nil, false
+	}
+
+	identityFile, _ := cfg.Get(host, "IdentityFile")
+	if identityFile == "" {
+		return nil, false
+	}
+	if strings.HasPrefix(identityFile, "~") {
+		identityFile = filepath.Join(homePath, strings.TrimPrefix(identityFile, "~"))
+	}
+
+	if configUser, _ := cfg.Get(host, "User"); configUser != "" {
+		user = configUser
+	}
+	if user == "" {
+		user = "git"
+	}
+
+	signer, err := ssh.NewPublicKeysFromFile(user, identityFile, "")
+	if isPassphraseError(err) {
+		signer, err = ssh.NewPublicKeysFromFile(user, identityFile, promptForPassphrase(identityFile))
+	}
+	if err != nil {
+		message.Debugf("Unable to load SSH key %s: %s", identityFile, err.Error())
+		return nil, false
+	}
+
+	return signer, true
+}
+
+// isPassphraseError reports whether err indicates an encrypted private key that needs a
+// passphrase, as opposed to e.g. the file not existing. Legacy PEM-format keys fail to
+// decrypt with a message containing "decrypt"; the OpenSSH format used by default since
+// OpenSSH 7.8 (most ed25519 keys) instead reports "this private key is passphrase
+// protected".
+func isPassphraseError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "decrypt") || strings.Contains(msg, "passphrase protected")
+}
+
+// promptForPassphrase asks the user, on the terminal, for the passphrase protecting path.
+func promptForPassphrase(path string) string {
+	fmt.Printf("Enter passphrase for key '%s': ", path)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		message.Debugf("Unable to read passphrase: %s", err.Error())
+		return ""
+	}
+
+	return string(passphrase)
+}
+
+// askPassAuth shells out to $GIT_ASKPASS (the same mechanism `git` itself uses) to
+// request a username and password for host.
+func askPassAuth(host, user string) (transport.AuthMethod, bool) {
+	askpass := os.Getenv("GIT_ASKPASS")
+	if askpass == "" {
+		return nil, false
+	}
+
+	username := user
+	if username == "" {
+		username, _ = runAskPass(askpass, fmt.Sprintf("Username for '%s':", host))
+	}
+
+	password, err := runAskPass(askpass, fmt.Sprintf("Password for '%s':", host))
+	if err != nil || password == "" {
+		return nil, false
+	}
+
+	return &http.BasicAuth{Username: username, Password: password}, true
+}
+
+func runAskPass(askpass, prompt string) (string, error) {
+	cmd := exec.Command(askpass, prompt)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sshAgentAuth builds an AuthMethod backed by a running ssh-agent (SSH_AUTH_SOCK), so
+// keys that were never written to disk (e.g. hardware-backed keys) still authenticate.
+func sshAgentAuth(user string) (transport.AuthMethod, bool) {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return nil, false
+	}
+	if user == "" {
+		user = "git"
+	}
+
+	auth, err := ssh.NewSSHAgentAuth(user)
+	if err != nil {
+		message.Debugf("Unable to connect to ssh-agent: %s", err.Error())
+		return nil, false
+	}
+
+	return auth, true
+}